@@ -10,6 +10,9 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs/memcached"
 	"github.com/influxdata/telegraf/plugins/inputs/procstat"
 	"github.com/influxdata/telegraf/plugins/parsers"
+
+	"github.com/influxdata/config"
+	"github.com/influxdata/toml/ast"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -127,3 +130,61 @@ func TestConfig_LoadDirectory(t *testing.T) {
 	assert.Equal(t, pConfig, c.Inputs[3].Config,
 		"Merged Testdata did not produce correct procstat metadata.")
 }
+
+func TestConfig_LoadLTSVParser(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfig("./testdata/ltsv_parser.toml")
+	assert.NoError(t, err)
+
+	ex := inputs.Inputs["exec"]().(*exec.Exec)
+	p, err := parsers.NewLTSVParser("exec", "time_local",
+		"02/Jan/2006:15:04:05 -0700",
+		[]string{"ua"},
+		[]string{"status", "body_bytes_sent"},
+		[]string{"request_time"},
+		[]string{"ssl"},
+		[]string{"host"},
+		true,
+		[]string{"yes"}, []string{"no"}, true,
+		[]string{"http_"},
+		"Asia/Tokyo",
+		[]string{"time_iso8601"},
+		[]string{"2006-01-02T15:04:05Z07:00"},
+		nil)
+	assert.NoError(t, err)
+	ex.SetParser(p)
+	ex.Command = "/usr/bin/mycollector --foo=bar"
+	assert.Equal(t, ex, c.Inputs[0].Input,
+		"Testdata did not produce a correct exec struct with an ltsv parser.")
+}
+
+func TestBuildParser_RemovesLTSVKeys(t *testing.T) {
+	tbl, err := config.ParseFile("./testdata/ltsv_parser.toml")
+	assert.NoError(t, err)
+	execTbl := tbl.Fields["inputs"].(*ast.Table).Fields["exec"].([]*ast.Table)[0]
+
+	_, err = buildParser("exec", execTbl)
+	assert.NoError(t, err)
+
+	for _, key := range []string{
+		"data_format",
+		"time_label",
+		"time_labels",
+		"time_format",
+		"time_formats",
+		"time_zone",
+		"str_field_labels",
+		"int_field_labels",
+		"float_field_labels",
+		"bool_field_labels",
+		"tag_labels",
+		"trim_values",
+		"bool_true_values",
+		"bool_false_values",
+		"bool_case_insensitive",
+		"tag_label_prefixes",
+	} {
+		assert.NotContains(t, execTbl.Fields, key)
+	}
+	assert.Contains(t, execTbl.Fields, "command")
+}
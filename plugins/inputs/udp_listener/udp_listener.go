@@ -43,7 +43,7 @@ const sampleConfig = `
   ## usually 1500 bytes, but can be as large as 65,535 bytes.
   udp_packet_size = 1500
 
-  ## Data format to consume. This can be "json", "influx" or "graphite"
+  ## Data format to consume. This can be "json", "influx", "graphite" or "ltsv"
   ## Each data format has it's own unique set of configuration options, read
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
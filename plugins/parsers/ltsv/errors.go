@@ -0,0 +1,21 @@
+package ltsv
+
+import "fmt"
+
+// A ParseError is returned when the value of a label can not be converted
+// to its configured type.
+type ParseError struct {
+	Label string
+	Value string
+	Err   error
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("label %q: value %q could not be parsed: %s",
+		err.Label, err.Value, err.Err)
+}
+
+// Unwrap returns the underlying strconv or time error.
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
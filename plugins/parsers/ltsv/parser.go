@@ -0,0 +1,336 @@
+package ltsv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	// DefaultTimeLabel is the label used for the metric timestamp when
+	// neither TimeLabel nor TimeLabels is set.
+	DefaultTimeLabel = "time"
+	// DefaultTimeFormat is the layout used to parse the timestamp when
+	// TimeFormat is not set. It matches nginx's $time_iso8601 and also
+	// accepts a "Z" suffix for UTC.
+	DefaultTimeFormat = time.RFC3339
+)
+
+// LTSVParser parses Labeled Tab-separated Values (http://ltsv.org/).
+// Labels not listed in any of the field or tag label lists, and not matching
+// one of the tag label prefixes, are ignored.
+type LTSVParser struct {
+	MetricName          string
+	TimeLabel           string
+	TimeFormat          string
+	StrFieldLabels      []string
+	IntFieldLabels      []string
+	FloatFieldLabels    []string
+	BoolFieldLabels     []string
+	TagLabels           []string
+	TrimValues          bool
+	BoolTrueValues      []string
+	BoolFalseValues     []string
+	BoolCaseInsensitive bool
+	TagLabelPrefixes    []string
+	TimeZone            string
+	TimeLabels          []string
+	TimeFormats         []string
+	DefaultTags         map[string]string
+
+	fieldTypes map[string]fieldType
+	tagSet     map[string]bool
+	boolValues map[string]bool
+	location   *time.Location
+
+	timeLabels   []string
+	timeLabelSet map[string]bool
+	timeFormats  []string
+}
+
+type fieldType int
+
+const (
+	strFieldType fieldType = iota
+	intFieldType
+	floatFieldType
+	boolFieldType
+)
+
+func NewLTSVParser(
+	metricName string,
+	timeLabel string,
+	timeFormat string,
+	strFieldLabels []string,
+	intFieldLabels []string,
+	floatFieldLabels []string,
+	boolFieldLabels []string,
+	tagLabels []string,
+	trimValues bool,
+	boolTrueValues []string,
+	boolFalseValues []string,
+	boolCaseInsensitive bool,
+	tagLabelPrefixes []string,
+	timeZone string,
+	timeLabels []string,
+	timeFormats []string,
+	defaultTags map[string]string,
+) (*LTSVParser, error) {
+	if timeLabel == "" && len(timeLabels) == 0 {
+		timeLabel = DefaultTimeLabel
+	}
+	if timeFormat == "" {
+		timeFormat = DefaultTimeFormat
+	}
+	p := &LTSVParser{
+		MetricName:          metricName,
+		TimeLabel:           timeLabel,
+		TimeFormat:          timeFormat,
+		StrFieldLabels:      strFieldLabels,
+		IntFieldLabels:      intFieldLabels,
+		FloatFieldLabels:    floatFieldLabels,
+		BoolFieldLabels:     boolFieldLabels,
+		TagLabels:           tagLabels,
+		TrimValues:          trimValues,
+		BoolTrueValues:      boolTrueValues,
+		BoolFalseValues:     boolFalseValues,
+		BoolCaseInsensitive: boolCaseInsensitive,
+		TagLabelPrefixes:    tagLabelPrefixes,
+		TimeZone:            timeZone,
+		TimeLabels:          timeLabels,
+		TimeFormats:         timeFormats,
+		DefaultTags:         defaultTags,
+	}
+
+	p.location = time.UTC
+	if timeZone != "" {
+		loc, err := time.LoadLocation(timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("ltsv parser: invalid time zone %q: %s", timeZone, err)
+		}
+		p.location = loc
+	}
+
+	p.fieldTypes = make(map[string]fieldType)
+	p.tagSet = make(map[string]bool)
+	labelLists := []struct {
+		labels []string
+		typ    fieldType
+	}{
+		{strFieldLabels, strFieldType},
+		{intFieldLabels, intFieldType},
+		{floatFieldLabels, floatFieldType},
+		{boolFieldLabels, boolFieldType},
+	}
+	for _, l := range labelLists {
+		for _, label := range l.labels {
+			if _, ok := p.fieldTypes[label]; ok {
+				return nil, fmt.Errorf("ltsv parser: label %q is listed in more than one field type", label)
+			}
+			p.fieldTypes[label] = l.typ
+		}
+	}
+	for _, label := range tagLabels {
+		if _, ok := p.fieldTypes[label]; ok {
+			return nil, fmt.Errorf("ltsv parser: label %q is listed as both a field and a tag", label)
+		}
+		p.tagSet[label] = true
+	}
+	p.boolValues = make(map[string]bool)
+	for _, v := range boolTrueValues {
+		p.boolValues[p.boolKey(v)] = true
+	}
+	for _, v := range boolFalseValues {
+		if _, ok := p.boolValues[p.boolKey(v)]; ok {
+			return nil, fmt.Errorf("ltsv parser: bool value %q is listed as both true and false", v)
+		}
+		p.boolValues[p.boolKey(v)] = false
+	}
+	// The time label is tried first, followed by the fallback time labels.
+	p.timeLabelSet = make(map[string]bool)
+	for _, label := range append([]string{timeLabel}, timeLabels...) {
+		if label == "" || p.timeLabelSet[label] {
+			continue
+		}
+		if _, ok := p.fieldTypes[label]; ok {
+			return nil, fmt.Errorf("ltsv parser: label %q is listed as both a time label and a field", label)
+		}
+		if p.tagSet[label] {
+			return nil, fmt.Errorf("ltsv parser: label %q is listed as both a time label and a tag", label)
+		}
+		p.timeLabels = append(p.timeLabels, label)
+		p.timeLabelSet[label] = true
+	}
+	// The time format is tried first, followed by the fallback time formats.
+	p.timeFormats = append([]string{timeFormat}, timeFormats...)
+	return p, nil
+}
+
+// Parse parses newline separated LTSV records. If any lines fail to parse,
+// a non-nil error will be returned in addition to the metrics that parsed
+// successfully. When only one line failed, the error is returned as is, so
+// a *ParseError can still be inspected by the caller.
+func (p *LTSVParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics := make([]telegraf.Metric, 0)
+
+	var errs []error
+	reader := bufio.NewReader(bytes.NewReader(buf))
+	for {
+		buf, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return metrics, err
+		}
+
+		line := strings.TrimRight(string(buf), "\r\n")
+		if line != "" {
+			metric, err := p.ParseLine(line)
+			if err == nil {
+				metrics = append(metrics, metric)
+			} else {
+				errs = append(errs, err)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if len(errs) == 1 {
+		return metrics, errs[0]
+	}
+
+	var errStr string
+	for _, err := range errs {
+		errStr += err.Error() + "\n"
+	}
+	if errStr != "" {
+		return metrics, errors.New(errStr)
+	}
+	return metrics, nil
+}
+
+// ParseLine parses a single LTSV record into a metric. If the record has none
+// of the time labels, the current time is used. Timestamps without a zone are
+// interpreted in TimeZone, or UTC when it is not set.
+func (p *LTSVParser) ParseLine(line string) (telegraf.Metric, error) {
+	fields := make(map[string]interface{})
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	var timeValues map[string]string
+
+	for _, term := range strings.Split(line, "\t") {
+		kv := strings.SplitN(term, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		label, value := kv[0], kv[1]
+		if p.TrimValues {
+			value = strings.TrimSpace(value)
+		}
+
+		if p.timeLabelSet[label] {
+			if timeValues == nil {
+				timeValues = make(map[string]string)
+			}
+			timeValues[label] = value
+			continue
+		}
+
+		if typ, ok := p.fieldTypes[label]; ok {
+			switch typ {
+			case strFieldType:
+				fields[label] = value
+			case intFieldType:
+				v, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return nil, &ParseError{Label: label, Value: value, Err: err}
+				}
+				fields[label] = v
+			case floatFieldType:
+				v, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, &ParseError{Label: label, Value: value, Err: err}
+				}
+				fields[label] = v
+			case boolFieldType:
+				v, ok := p.boolValues[p.boolKey(value)]
+				if !ok {
+					var err error
+					v, err = strconv.ParseBool(value)
+					if err != nil {
+						return nil, &ParseError{Label: label, Value: value, Err: err}
+					}
+				}
+				fields[label] = v
+			}
+		} else if p.tagSet[label] || p.hasTagLabelPrefix(label) {
+			tags[label] = value
+		}
+	}
+
+	t, err := p.parseTime(timeValues)
+	if err != nil {
+		return nil, err
+	}
+	return telegraf.NewMetric(p.MetricName, tags, fields, t)
+}
+
+// parseTime returns the timestamp of the first time label, in order, whose
+// value parses with one of the time formats. If none of the time labels are
+// present, the current time is returned.
+func (p *LTSVParser) parseTime(values map[string]string) (time.Time, error) {
+	var firstErr error
+	for _, label := range p.timeLabels {
+		value, ok := values[label]
+		if !ok {
+			continue
+		}
+		for _, format := range p.timeFormats {
+			t, err := time.ParseInLocation(format, value, p.location)
+			if err == nil {
+				return t, nil
+			}
+			if firstErr == nil {
+				firstErr = &ParseError{Label: label, Value: value, Err: err}
+			}
+		}
+	}
+	if firstErr != nil {
+		return time.Time{}, firstErr
+	}
+	return time.Now().UTC(), nil
+}
+
+// hasTagLabelPrefix reports whether label starts with one of the
+// configured tag label prefixes.
+func (p *LTSVParser) hasTagLabelPrefix(label string) bool {
+	for _, prefix := range p.TagLabelPrefixes {
+		if strings.HasPrefix(label, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// boolKey returns the key used to look up value in the configured
+// true/false literals.
+func (p *LTSVParser) boolKey(value string) string {
+	if p.BoolCaseInsensitive {
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+func (p *LTSVParser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
@@ -0,0 +1,429 @@
+package ltsv
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validLTSV = "time:2016-03-03T13:58:57+00:00\thost:localhost\tstatus:200\t" +
+	"request_time:0.123\tssl:true\tua:curl/7.43.0\tignored:foo"
+
+const validLTSVMultiline = "\n" +
+	"time:2016-03-03T13:58:57+00:00\thost:localhost\tstatus:200\n" +
+	"time:2016-03-03T13:58:58+00:00\thost:remotehost\tstatus:404\r\n"
+
+func newTestParser(t *testing.T) *LTSVParser {
+	parser, err := NewLTSVParser("ltsv_test", "", "",
+		[]string{"ua"},
+		[]string{"status"},
+		[]string{"request_time"},
+		[]string{"ssl"},
+		[]string{"host"},
+		false,
+		nil, nil, false,
+		nil,
+		"",
+		nil,
+		nil,
+		nil)
+	assert.NoError(t, err)
+	return parser
+}
+
+func TestParseLineValidLTSV(t *testing.T) {
+	parser := newTestParser(t)
+
+	metric, err := parser.ParseLine(validLTSV)
+	assert.NoError(t, err)
+	assert.Equal(t, "ltsv_test", metric.Name())
+	assert.Equal(t, map[string]interface{}{
+		"ua":           "curl/7.43.0",
+		"status":       int64(200),
+		"request_time": float64(0.123),
+		"ssl":          true,
+	}, metric.Fields())
+	assert.Equal(t, map[string]string{"host": "localhost"}, metric.Tags())
+	assert.Equal(t, time.Date(2016, 3, 3, 13, 58, 57, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+}
+
+func TestParseValidLTSV(t *testing.T) {
+	parser := newTestParser(t)
+
+	metrics, err := parser.Parse([]byte(validLTSVMultiline))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, map[string]interface{}{"status": int64(200)}, metrics[0].Fields())
+	assert.Equal(t, map[string]string{"host": "localhost"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"status": int64(404)}, metrics[1].Fields())
+	assert.Equal(t, map[string]string{"host": "remotehost"}, metrics[1].Tags())
+}
+
+func TestParseLineCustomTime(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "time_local",
+		"02/Jan/2006:15:04:05 -0700", nil, []string{"status"}, nil, nil, nil, false, nil, nil, false, nil, "", nil, nil, nil)
+	assert.NoError(t, err)
+
+	metric, err := parser.ParseLine("time_local:03/Mar/2016:13:58:57 +0900\tstatus:200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 3, 3, 4, 58, 57, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+}
+
+func TestParseLineUTCTime(t *testing.T) {
+	parser := newTestParser(t)
+
+	metric, err := parser.ParseLine("time:2016-03-03T13:58:57Z\tstatus:200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 3, 3, 13, 58, 57, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+}
+
+func TestParseLineMissingTime(t *testing.T) {
+	parser := newTestParser(t)
+
+	before := time.Now()
+	metric, err := parser.ParseLine("status:200")
+	assert.NoError(t, err)
+	assert.False(t, metric.Time().Before(before.Add(-time.Second)))
+}
+
+func TestParseLineDefaultTags(t *testing.T) {
+	parser := newTestParser(t)
+	parser.SetDefaultTags(map[string]string{"env": "prod", "host": "default"})
+
+	metric, err := parser.ParseLine(validLTSV)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"env":  "prod",
+		"host": "localhost",
+	}, metric.Tags())
+}
+
+func TestParseLineInvalidValues(t *testing.T) {
+	parser := newTestParser(t)
+
+	_, err := parser.ParseLine("status:abc")
+	assert.Error(t, err)
+	_, err = parser.ParseLine("request_time:abc")
+	assert.Error(t, err)
+	_, err = parser.ParseLine("ssl:maybe")
+	assert.Error(t, err)
+	_, err = parser.ParseLine("time:yesterday\tstatus:200")
+	assert.Error(t, err)
+}
+
+func TestParseInvalidLine(t *testing.T) {
+	parser := newTestParser(t)
+
+	metrics, err := parser.Parse([]byte("status:200\nstatus:abc\n"))
+	assert.Error(t, err)
+	assert.Len(t, metrics, 1)
+}
+
+func TestNewLTSVParserDuplicateLabel(t *testing.T) {
+	_, err := NewLTSVParser("ltsv_test", "", "",
+		[]string{"status"}, []string{"status"}, nil, nil, nil, false, nil, nil, false, nil, "", nil, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"status"}, nil, nil, []string{"status"}, false, nil, nil, false, nil, "", nil, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewLTSVParser("ltsv_test", "", "",
+		[]string{"time"}, nil, nil, nil, nil, false, nil, nil, false, nil, "", nil, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewLTSVParser("ltsv_test", "time_local", "",
+		nil, nil, nil, nil, []string{"time_local"}, false, nil, nil, false, nil, "", nil, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"time_local"}, nil, nil, nil, false, nil, nil, false, nil, "",
+		[]string{"time", "time_local"}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewLTSVParser("ltsv_test", "", "",
+		nil, nil, nil, nil, []string{"time_local"}, false, nil, nil, false, nil, "",
+		[]string{"time", "time_local"}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestParseLineParseError(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"body_bytes_sent"}, nil, nil, nil, false, nil, nil, false, nil, "", nil, nil, nil)
+	assert.NoError(t, err)
+
+	_, err = parser.ParseLine("body_bytes_sent:abc")
+	assertParseError(t, err, "body_bytes_sent", "abc")
+	assert.Contains(t, err.Error(), "body_bytes_sent")
+}
+
+func TestParseParseError(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"body_bytes_sent"}, nil, nil, nil, false, nil, nil, false, nil, "", nil, nil, nil)
+	assert.NoError(t, err)
+
+	// A single bad line is returned as its *ParseError
+	metrics, err := parser.Parse([]byte("body_bytes_sent:612\nbody_bytes_sent:100%25\n"))
+	assert.Len(t, metrics, 1)
+	assertParseError(t, err, "body_bytes_sent", "100%25")
+	assert.Contains(t, err.Error(), `"100%25"`)
+
+	// Several bad lines are reported together
+	metrics, err = parser.Parse([]byte("body_bytes_sent:100%25\n" +
+		"body_bytes_sent:612\nbody_bytes_sent:abc\n"))
+	assert.Len(t, metrics, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `"100%25"`)
+	assert.Contains(t, err.Error(), `"abc"`)
+}
+
+func assertParseError(t *testing.T, err error, label, value string) {
+	perr, ok := err.(*ParseError)
+	if !assert.True(t, ok, "expected *ParseError, got %T", err) {
+		return
+	}
+	assert.Equal(t, label, perr.Label)
+	assert.Equal(t, value, perr.Value)
+	if numErr, ok := perr.Err.(*strconv.NumError); assert.True(t, ok) {
+		assert.Equal(t, strconv.ErrSyntax, numErr.Err)
+	}
+}
+
+func TestParseTrimValues(t *testing.T) {
+	crlf := "time:2016-03-03T13:58:57+00:00\thost: localhost \tstatus: 200\r\n" +
+		"time:2016-03-03T13:58:58+00:00\thost:remotehost\tstatus:404 \t\r\n"
+
+	parser := newTestParser(t)
+	_, err := parser.Parse([]byte(crlf))
+	assert.Error(t, err)
+
+	parser.TrimValues = true
+	metrics, err := parser.Parse([]byte(crlf))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, map[string]interface{}{"status": int64(200)}, metrics[0].Fields())
+	assert.Equal(t, map[string]string{"host": "localhost"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"status": int64(404)}, metrics[1].Fields())
+
+	metric, err := parser.ParseLine("request_time:0.5\r")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"request_time": float64(0.5)}, metric.Fields())
+}
+
+func TestParseLineBoolValues(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "", "",
+		nil, nil, nil, []string{"ssl"}, nil,
+		false,
+		[]string{"yes", "on"},
+		[]string{"no", "off"},
+		false,
+		nil,
+		"",
+		nil,
+		nil,
+		nil)
+	assert.NoError(t, err)
+
+	metric, err := parser.ParseLine("ssl:yes")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ssl": true}, metric.Fields())
+	metric, err = parser.ParseLine("ssl:no")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ssl": false}, metric.Fields())
+
+	// Falls back to strconv.ParseBool
+	metric, err = parser.ParseLine("ssl:TRUE")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ssl": true}, metric.Fields())
+
+	// Literals are case-sensitive by default
+	_, err = parser.ParseLine("ssl:ON")
+	assert.Error(t, err)
+
+	parser, err = NewLTSVParser("ltsv_test", "", "",
+		nil, nil, nil, []string{"ssl"}, nil,
+		false,
+		[]string{"yes", "on"},
+		[]string{"no", "off"},
+		true,
+		nil,
+		"",
+		nil,
+		nil,
+		nil)
+	assert.NoError(t, err)
+	metric, err = parser.ParseLine("ssl:ON")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ssl": true}, metric.Fields())
+	metric, err = parser.ParseLine("ssl:OFF")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ssl": false}, metric.Fields())
+}
+
+func TestNewLTSVParserConflictingBoolValues(t *testing.T) {
+	_, err := NewLTSVParser("ltsv_test", "", "",
+		nil, nil, nil, []string{"ssl"}, nil,
+		false,
+		[]string{"y"},
+		[]string{"Y"},
+		true,
+		nil,
+		"",
+		nil,
+		nil,
+		nil)
+	assert.Error(t, err)
+}
+
+func TestParseLineTagLabelPrefixes(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"status", "http_status"}, nil, nil, []string{"host"},
+		false, nil, nil, false,
+		[]string{"http_"},
+		"",
+		nil,
+		nil,
+		nil)
+	assert.NoError(t, err)
+
+	metric, err := parser.ParseLine("host:localhost\tstatus:200\thttp_status:200\t" +
+		"http_host:example.com\thttp_referer:-\tua:curl")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"host":         "localhost",
+		"http_host":    "example.com",
+		"http_referer": "-",
+	}, metric.Tags())
+	// Field labels win over tag prefixes
+	assert.Equal(t, map[string]interface{}{
+		"status":      int64(200),
+		"http_status": int64(200),
+	}, metric.Fields())
+}
+
+func TestParseLineTimeZone(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "time_local",
+		"02/Jan/2006:15:04:05", nil, []string{"status"}, nil, nil, nil,
+		false, nil, nil, false, nil,
+		"Asia/Tokyo",
+		nil,
+		nil,
+		nil)
+	assert.NoError(t, err)
+
+	metric, err := parser.ParseLine("time_local:03/Mar/2016:13:58:57\tstatus:200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 3, 3, 4, 58, 57, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+
+	// An explicit offset in the timestamp wins over the time zone
+	parser, err = NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"status"}, nil, nil, nil,
+		false, nil, nil, false, nil,
+		"Asia/Tokyo",
+		nil,
+		nil,
+		nil)
+	assert.NoError(t, err)
+	metric, err = parser.ParseLine("time:2016-03-03T13:58:57Z\tstatus:200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 3, 3, 13, 58, 57, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+}
+
+func TestNewLTSVParserInvalidTimeZone(t *testing.T) {
+	_, err := NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"status"}, nil, nil, nil,
+		false, nil, nil, false, nil,
+		"Mars/Olympus_Mons",
+		nil,
+		nil,
+		nil)
+	assert.Error(t, err)
+}
+
+func TestParseLineTimeLabels(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"status"}, nil, nil, nil,
+		false, nil, nil, false, nil, "",
+		[]string{"time", "time_iso8601"},
+		nil,
+		nil)
+	assert.NoError(t, err)
+
+	// The first label is absent, so the second one is used
+	metric, err := parser.ParseLine("time_iso8601:2016-03-03T13:58:57Z\tstatus:200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 3, 3, 13, 58, 57, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+
+	// The first label wins when both are present
+	metric, err = parser.ParseLine("time_iso8601:2016-03-03T13:58:57Z\t" +
+		"time:2016-03-03T14:00:00Z\tstatus:200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 3, 3, 14, 0, 0, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+
+	// A label that fails to parse falls back to the next one
+	metric, err = parser.ParseLine("time:-\ttime_iso8601:2016-03-03T13:58:57Z\tstatus:200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 3, 3, 13, 58, 57, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+
+	_, err = parser.ParseLine("time:-\tstatus:200")
+	assertTimeParseError(t, err, "time", "-")
+}
+
+func TestParseLineTimeLabelBeforeTimeLabels(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "time_local", "",
+		nil, []string{"status"}, nil, nil, nil,
+		false, nil, nil, false, nil, "",
+		[]string{"time"},
+		nil,
+		nil)
+	assert.NoError(t, err)
+
+	metric, err := parser.ParseLine("time:2016-03-03T14:00:00Z\t" +
+		"time_local:2016-03-03T13:58:57Z\tstatus:200")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 3, 3, 13, 58, 57, 0, time.UTC).UnixNano(),
+		metric.Time().UnixNano())
+}
+
+func assertTimeParseError(t *testing.T, err error, label, value string) {
+	perr, ok := err.(*ParseError)
+	if !assert.True(t, ok, "expected *ParseError, got %T", err) {
+		return
+	}
+	assert.Equal(t, label, perr.Label)
+	assert.Equal(t, value, perr.Value)
+	_, ok = perr.Err.(*time.ParseError)
+	assert.True(t, ok)
+}
+
+func TestParseTimeFormats(t *testing.T) {
+	parser, err := NewLTSVParser("ltsv_test", "", "",
+		nil, []string{"status"}, nil, nil, nil,
+		false, nil, nil, false, nil, "", nil,
+		[]string{time.RFC1123},
+		nil)
+	assert.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(
+		"time:2016-03-03T13:58:57+00:00\tstatus:200\n" +
+			"time:Thu, 03 Mar 2016 13:58:58 GMT\tstatus:404\n"))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, time.Date(2016, 3, 3, 13, 58, 57, 0, time.UTC).UnixNano(),
+		metrics[0].Time().UnixNano())
+	assert.Equal(t, time.Date(2016, 3, 3, 13, 58, 58, 0, time.UTC).UnixNano(),
+		metrics[1].Time().UnixNano())
+
+	_, err = parser.ParseLine("time:03/Mar/2016:13:58:57 +0000\tstatus:200")
+	assertTimeParseError(t, err, "time", "03/Mar/2016:13:58:57 +0000")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/graphite"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
+	"github.com/influxdata/telegraf/plugins/parsers/ltsv"
 )
 
 // ParserInput is an interface for input plugins that are able to parse
@@ -38,7 +39,7 @@ type Parser interface {
 // Config is a struct that covers the data types needed for all parser types,
 // and can be used to instantiate _any_ of the parsers.
 type Config struct {
-	// Dataformat can be one of: json, influx, graphite
+	// Dataformat can be one of: json, influx, graphite, ltsv
 	DataFormat string
 
 	// Separator only applied to Graphite data.
@@ -48,9 +49,43 @@ type Config struct {
 
 	// TagKeys only apply to JSON data
 	TagKeys []string
-	// MetricName only applies to JSON data. This will be the name of the measurement.
+	// MetricName applies to JSON and LTSV data. This will be the name of the measurement.
 	MetricName string
 
+	// TimeLabel only applies to LTSV data. This is the label of the timestamp.
+	TimeLabel string
+	// TimeFormat only applies to LTSV data. This is the layout of the timestamp.
+	TimeFormat string
+	// StrFieldLabels only apply to LTSV data
+	StrFieldLabels []string
+	// IntFieldLabels only apply to LTSV data
+	IntFieldLabels []string
+	// FloatFieldLabels only apply to LTSV data
+	FloatFieldLabels []string
+	// BoolFieldLabels only apply to LTSV data
+	BoolFieldLabels []string
+	// TagLabels only apply to LTSV data
+	TagLabels []string
+	// TrimValues only applies to LTSV data
+	TrimValues bool
+	// BoolTrueValues only apply to LTSV data
+	BoolTrueValues []string
+	// BoolFalseValues only apply to LTSV data
+	BoolFalseValues []string
+	// BoolCaseInsensitive only applies to LTSV data
+	BoolCaseInsensitive bool
+	// TagLabelPrefixes only apply to LTSV data
+	TagLabelPrefixes []string
+	// TimeZone only applies to LTSV data. This is the IANA name of the
+	// location used for timestamps without a zone.
+	TimeZone string
+	// TimeLabels only apply to LTSV data. These are fallback labels of the
+	// timestamp, tried in order after TimeLabel.
+	TimeLabels []string
+	// TimeFormats only apply to LTSV data. These are fallback layouts of the
+	// timestamp, tried in order after TimeFormat.
+	TimeFormats []string
+
 	// DefaultTags are the default tags that will be added to all parsed metrics.
 	DefaultTags map[string]string
 }
@@ -68,6 +103,19 @@ func NewParser(config *Config) (Parser, error) {
 	case "graphite":
 		parser, err = NewGraphiteParser(config.Separator,
 			config.Templates, config.DefaultTags)
+	case "ltsv":
+		parser, err = NewLTSVParser(config.MetricName,
+			config.TimeLabel, config.TimeFormat,
+			config.StrFieldLabels, config.IntFieldLabels,
+			config.FloatFieldLabels, config.BoolFieldLabels,
+			config.TagLabels, config.TrimValues,
+			config.BoolTrueValues, config.BoolFalseValues,
+			config.BoolCaseInsensitive,
+			config.TagLabelPrefixes,
+			config.TimeZone,
+			config.TimeLabels,
+			config.TimeFormats,
+			config.DefaultTags)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -98,3 +146,33 @@ func NewGraphiteParser(
 ) (Parser, error) {
 	return graphite.NewGraphiteParser(separator, templates, defaultTags)
 }
+
+func NewLTSVParser(
+	metricName string,
+	timeLabel string,
+	timeFormat string,
+	strFieldLabels []string,
+	intFieldLabels []string,
+	floatFieldLabels []string,
+	boolFieldLabels []string,
+	tagLabels []string,
+	trimValues bool,
+	boolTrueValues []string,
+	boolFalseValues []string,
+	boolCaseInsensitive bool,
+	tagLabelPrefixes []string,
+	timeZone string,
+	timeLabels []string,
+	timeFormats []string,
+	defaultTags map[string]string,
+) (Parser, error) {
+	return ltsv.NewLTSVParser(metricName, timeLabel, timeFormat,
+		strFieldLabels, intFieldLabels, floatFieldLabels, boolFieldLabels,
+		tagLabels, trimValues,
+		boolTrueValues, boolFalseValues, boolCaseInsensitive,
+		tagLabelPrefixes,
+		timeZone,
+		timeLabels,
+		timeFormats,
+		defaultTags)
+}